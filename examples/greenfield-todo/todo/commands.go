@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -21,18 +22,15 @@ func addTask(args []string) {
 		os.Exit(1)
 	}
 
-	todoData, err := LoadTodos()
+	var taskID int
+	err := WithTodos(func(todoData *TodoData) error {
+		task := NewTask(todoData.NextID, description)
+		todoData.Tasks[todoData.NextID] = task
+		taskID = todoData.NextID
+		todoData.NextID++
+		return nil
+	})
 	if err != nil {
-		fmt.Printf("Error loading todos: %v\n", err)
-		os.Exit(2)
-	}
-
-	task := NewTask(todoData.NextID, description)
-	todoData.Tasks[todoData.NextID] = task
-	taskID := todoData.NextID
-	todoData.NextID++
-
-	if err := SaveTodos(todoData); err != nil {
 		fmt.Printf("Error saving todos: %v\n", err)
 		os.Exit(2)
 	}
@@ -40,48 +38,224 @@ func addTask(args []string) {
 	fmt.Printf("Task %d added\n", taskID)
 }
 
-// listTasks displays tasks (active only or all)
-func listTasks(showAll bool) {
+// listTasks writes tasks to w, optionally narrowed by a filter query,
+// reordered by --sort/--reverse, and rendered by --format=plain|todotxt|json|
+// i3status (default plain). Any argument that isn't one of those flags,
+// --all, or --include-blocked is treated as part of the filter query. The json format always dumps the
+// full, unfiltered TodoData for scripting.
+func listTasks(w io.Writer, args []string) {
+	showAll := false
+	includeBlocked := false
+	sortBy := "id"
+	reverse := false
+	format := "plain"
+	var queryWords []string
+
+	for _, arg := range args {
+		switch {
+		case arg == "--all":
+			showAll = true
+		case arg == "--include-blocked":
+			includeBlocked = true
+		case arg == "--reverse":
+			reverse = true
+		case strings.HasPrefix(arg, "--sort="):
+			sortBy = strings.TrimPrefix(arg, "--sort=")
+		case strings.HasPrefix(arg, "--format="):
+			format = strings.TrimPrefix(arg, "--format=")
+		default:
+			queryWords = append(queryWords, arg)
+		}
+	}
+
 	todoData, err := LoadTodos()
 	if err != nil {
 		fmt.Printf("Error loading todos: %v\n", err)
 		os.Exit(2)
 	}
 
+	if format == "json" {
+		if err := writeTasksJSON(w, todoData); err != nil {
+			fmt.Printf("Error encoding todos: %v\n", err)
+			os.Exit(2)
+		}
+		return
+	}
+
 	if len(todoData.Tasks) == 0 {
-		fmt.Println("No tasks found")
+		fmt.Fprintln(w, "No tasks found")
 		return
 	}
 
-	hasActiveTasks := false
+	filter := ParseFilterQuery(strings.Join(queryWords, " "))
+
+	if format == "i3status" {
+		// The done/total counts need to reflect every task matching the
+		// query, not just the ones the default view shows, or "done" would
+		// always read 0 unless the caller remembered --all.
+		var statusTasks []*Task
+		for id := 1; id < todoData.NextID; id++ {
+			task, exists := todoData.Tasks[id]
+			if !exists || !filter(task) {
+				continue
+			}
+			statusTasks = append(statusTasks, task)
+		}
+
+		label := strings.Join(queryWords, " ")
+		if label == "" {
+			label = "all"
+		}
+		if err := writeTasksI3Status(w, label, statusTasks); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(2)
+		}
+		return
+	}
+
+	var tasks []*Task
 	for id := 1; id < todoData.NextID; id++ {
 		task, exists := todoData.Tasks[id]
 		if !exists {
 			continue
 		}
-
 		if !showAll && task.Completed {
 			continue
 		}
+		if !showAll && !includeBlocked && IsTaskBlocked(todoData, task) {
+			continue
+		}
+		if !filter(task) {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+
+	if len(tasks) == 0 {
+		fmt.Fprintln(w, "No matching tasks found")
+		return
+	}
+
+	SortTasks(tasks, sortBy, reverse)
+
+	switch format {
+	case "todotxt":
+		writeTasksTodoTxt(w, tasks)
+	default:
+		writeTasksPlain(w, tasks, todoData)
+	}
+}
+
+// completeTask marks a task as completed. It refuses to complete a task with
+// unresolved dependencies unless --force is given.
+func completeTask(args []string) {
+	force := false
+	var positional []string
+	for _, arg := range args {
+		if arg == "--force" {
+			force = true
+		} else {
+			positional = append(positional, arg)
+		}
+	}
+
+	if len(positional) == 0 {
+		fmt.Println("Error: Task ID required")
+		fmt.Println("Usage: todo complete <id> [--force]")
+		os.Exit(1)
+	}
+
+	id, err := strconv.Atoi(positional[0])
+	if err != nil {
+		fmt.Printf("Error: Invalid task ID: %s\n", positional[0])
+		os.Exit(1)
+	}
+
+	var message string
+	err = WithTodos(func(todoData *TodoData) error {
+		task, exists := todoData.Tasks[id]
+		if !exists {
+			fmt.Printf("Error: Task ID %d not found\n", id)
+			os.Exit(1)
+		}
 
-		hasActiveTasks = true
-		status := ""
 		if task.Completed {
-			status = " [DONE]"
+			message = fmt.Sprintf("Task %d already completed", id)
+			return nil
+		}
+
+		if !force && IsTaskBlocked(todoData, task) {
+			fmt.Printf("Error: Task %d has unresolved dependencies; use --force to complete anyway\n", id)
+			os.Exit(1)
 		}
-		fmt.Printf("%d: %s%s\n", task.ID, task.Description, status)
+
+		task.MarkCompleted()
+		message = fmt.Sprintf("Task %d completed", id)
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Error saving todos: %v\n", err)
+		os.Exit(2)
+	}
+
+	fmt.Println(message)
+}
+
+// editTask updates a task's description
+func editTask(args []string) {
+	if len(args) < 1 {
+		fmt.Println("Error: Task ID and new description required")
+		fmt.Println("Usage: todo edit <id> \"new description\"")
+		os.Exit(1)
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		fmt.Printf("Error: Invalid task ID: %s\n", args[0])
+		os.Exit(1)
+	}
+
+	if len(args) == 2 && args[1] == "--editor" {
+		editTaskInEditor(id)
+		return
+	}
+
+	if len(args) < 2 {
+		fmt.Println("Error: Task ID and new description required")
+		fmt.Println("Usage: todo edit <id> \"new description\"")
+		os.Exit(1)
 	}
 
-	if !hasActiveTasks && !showAll {
-		fmt.Println("No active tasks")
+	description := strings.Join(args[1:], " ")
+	if strings.TrimSpace(description) == "" {
+		fmt.Println("Error: Task description cannot be empty")
+		os.Exit(1)
 	}
+
+	err = WithTodos(func(todoData *TodoData) error {
+		task, exists := todoData.Tasks[id]
+		if !exists {
+			fmt.Printf("Error: Task ID %d not found\n", id)
+			os.Exit(1)
+		}
+
+		task.Description = description
+		task.Touch()
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Error saving todos: %v\n", err)
+		os.Exit(2)
+	}
+
+	fmt.Printf("Task %d updated\n", id)
 }
 
-// completeTask marks a task as completed
-func completeTask(args []string) {
+// deleteTask removes a task permanently
+func deleteTask(args []string) {
 	if len(args) == 0 {
 		fmt.Println("Error: Task ID required")
-		fmt.Println("Usage: todo complete <id>")
+		fmt.Println("Usage: todo delete <id>")
 		os.Exit(1)
 	}
 
@@ -91,38 +265,80 @@ func completeTask(args []string) {
 		os.Exit(1)
 	}
 
-	todoData, err := LoadTodos()
+	err = WithTodos(func(todoData *TodoData) error {
+		if _, exists := todoData.Tasks[id]; !exists {
+			fmt.Printf("Error: Task ID %d not found\n", id)
+			os.Exit(1)
+		}
+
+		delete(todoData.Tasks, id)
+		return nil
+	})
 	if err != nil {
-		fmt.Printf("Error loading todos: %v\n", err)
+		fmt.Printf("Error saving todos: %v\n", err)
 		os.Exit(2)
 	}
 
-	task, exists := todoData.Tasks[id]
-	if !exists {
-		fmt.Printf("Error: Task ID %d not found\n", id)
+	fmt.Printf("Task %d deleted\n", id)
+}
+
+// noteTask sets a task's free-form notes field
+func noteTask(args []string) {
+	if len(args) < 2 {
+		fmt.Println("Error: Task ID and note text required")
+		fmt.Println("Usage: todo note <id> \"text\"")
 		os.Exit(1)
 	}
 
-	if task.Completed {
-		fmt.Printf("Task %d already completed\n", id)
-		return
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		fmt.Printf("Error: Invalid task ID: %s\n", args[0])
+		os.Exit(1)
 	}
 
-	task.MarkCompleted()
+	err = WithTodos(func(todoData *TodoData) error {
+		task, exists := todoData.Tasks[id]
+		if !exists {
+			fmt.Printf("Error: Task ID %d not found\n", id)
+			os.Exit(1)
+		}
 
-	if err := SaveTodos(todoData); err != nil {
+		task.Notes = strings.Join(args[1:], " ")
+		task.Touch()
+		return nil
+	})
+	if err != nil {
 		fmt.Printf("Error saving todos: %v\n", err)
 		os.Exit(2)
 	}
 
-	fmt.Printf("Task %d completed\n", id)
+	fmt.Printf("Task %d note updated\n", id)
 }
 
-// editTask updates a task's description
-func editTask(args []string) {
+// subCommand dispatches `todo sub add|done ...` to the matching handler
+func subCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Error: Subtask action required")
+		fmt.Println("Usage: todo sub add <id> \"summary\" | todo sub done <id> <index>")
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "add":
+		addSubtask(args[1:])
+	case "done":
+		completeSubtask(args[1:])
+	default:
+		fmt.Printf("Error: Unknown sub command: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+// addSubtask appends a new, unresolved subtask to a task
+func addSubtask(args []string) {
 	if len(args) < 2 {
-		fmt.Println("Error: Task ID and new description required")
-		fmt.Println("Usage: todo edit <id> \"new description\"")
+		fmt.Println("Error: Task ID and summary required")
+		fmt.Println("Usage: todo sub add <id> \"summary\"")
 		os.Exit(1)
 	}
 
@@ -132,39 +348,74 @@ func editTask(args []string) {
 		os.Exit(1)
 	}
 
-	description := strings.Join(args[1:], " ")
-	if strings.TrimSpace(description) == "" {
-		fmt.Println("Error: Task description cannot be empty")
+	err = WithTodos(func(todoData *TodoData) error {
+		task, exists := todoData.Tasks[id]
+		if !exists {
+			fmt.Printf("Error: Task ID %d not found\n", id)
+			os.Exit(1)
+		}
+
+		task.Subtasks = append(task.Subtasks, SubTask{Summary: strings.Join(args[1:], " ")})
+		task.Touch()
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Error saving todos: %v\n", err)
+		os.Exit(2)
+	}
+
+	fmt.Printf("Subtask added to task %d\n", id)
+}
+
+// completeSubtask marks one of a task's subtasks, by index, as resolved
+func completeSubtask(args []string) {
+	if len(args) < 2 {
+		fmt.Println("Error: Task ID and subtask index required")
+		fmt.Println("Usage: todo sub done <id> <index>")
 		os.Exit(1)
 	}
 
-	todoData, err := LoadTodos()
+	id, err := strconv.Atoi(args[0])
 	if err != nil {
-		fmt.Printf("Error loading todos: %v\n", err)
-		os.Exit(2)
+		fmt.Printf("Error: Invalid task ID: %s\n", args[0])
+		os.Exit(1)
 	}
 
-	task, exists := todoData.Tasks[id]
-	if !exists {
-		fmt.Printf("Error: Task ID %d not found\n", id)
+	index, err := strconv.Atoi(args[1])
+	if err != nil {
+		fmt.Printf("Error: Invalid subtask index: %s\n", args[1])
 		os.Exit(1)
 	}
 
-	task.Description = description
+	err = WithTodos(func(todoData *TodoData) error {
+		task, exists := todoData.Tasks[id]
+		if !exists {
+			fmt.Printf("Error: Task ID %d not found\n", id)
+			os.Exit(1)
+		}
 
-	if err := SaveTodos(todoData); err != nil {
+		if index < 0 || index >= len(task.Subtasks) {
+			fmt.Printf("Error: Subtask index %d not found on task %d\n", index, id)
+			os.Exit(1)
+		}
+
+		task.Subtasks[index].Resolved = true
+		task.Touch()
+		return nil
+	})
+	if err != nil {
 		fmt.Printf("Error saving todos: %v\n", err)
 		os.Exit(2)
 	}
 
-	fmt.Printf("Task %d updated\n", id)
+	fmt.Printf("Subtask %d on task %d marked done\n", index, id)
 }
 
-// deleteTask removes a task permanently
-func deleteTask(args []string) {
-	if len(args) == 0 {
-		fmt.Println("Error: Task ID required")
-		fmt.Println("Usage: todo delete <id>")
+// blockTask makes a task depend on another task's completion
+func blockTask(args []string) {
+	if len(args) < 3 || args[1] != "--on" {
+		fmt.Println("Error: Task ID and --on <other-id> required")
+		fmt.Println("Usage: todo block <id> --on <other-id>")
 		os.Exit(1)
 	}
 
@@ -174,26 +425,85 @@ func deleteTask(args []string) {
 		os.Exit(1)
 	}
 
-	todoData, err := LoadTodos()
+	dependsOnID, err := strconv.Atoi(args[2])
 	if err != nil {
-		fmt.Printf("Error loading todos: %v\n", err)
+		fmt.Printf("Error: Invalid task ID: %s\n", args[2])
+		os.Exit(1)
+	}
+
+	err = WithTodos(func(todoData *TodoData) error {
+		task, exists := todoData.Tasks[id]
+		if !exists {
+			fmt.Printf("Error: Task ID %d not found\n", id)
+			os.Exit(1)
+		}
+
+		if _, exists := todoData.Tasks[dependsOnID]; !exists {
+			fmt.Printf("Error: Task ID %d not found\n", dependsOnID)
+			os.Exit(1)
+		}
+
+		task.DependsOn = append(task.DependsOn, dependsOnID)
+		task.Touch()
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Error saving todos: %v\n", err)
 		os.Exit(2)
 	}
 
-	_, exists := todoData.Tasks[id]
-	if !exists {
-		fmt.Printf("Error: Task ID %d not found\n", id)
+	fmt.Printf("Task %d now depends on task %d\n", id, dependsOnID)
+}
+
+// syncTasks reconciles the local store with the IMAP/SMTP mailbox configured
+// in ~/.todo.conf.yaml
+func syncTasks(args []string) {
+	config, err := LoadConfig()
+	if err != nil {
+		fmt.Printf("Error loading sync config: %v\n", err)
+		os.Exit(2)
+	}
+
+	changed, err := SyncTasks(config)
+	if err != nil {
+		fmt.Printf("Error syncing todos: %v\n", err)
+		os.Exit(2)
+	}
+
+	fmt.Printf("Sync complete, %d task(s) updated locally\n", changed)
+}
+
+// importTasks imports tasks from a todo.txt formatted file
+func importTasks(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Error: File path required")
+		fmt.Println("Usage: todo import <file>")
 		os.Exit(1)
 	}
 
-	delete(todoData.Tasks, id)
+	count, err := ImportTodoTxt(args[0])
+	if err != nil {
+		fmt.Printf("Error importing todos: %v\n", err)
+		os.Exit(2)
+	}
 
-	if err := SaveTodos(todoData); err != nil {
-		fmt.Printf("Error saving todos: %v\n", err)
+	fmt.Printf("Imported %d task(s) from %s\n", count, args[0])
+}
+
+// exportTasks exports tasks to a todo.txt formatted file
+func exportTasks(args []string) {
+	if len(args) == 0 {
+		fmt.Println("Error: File path required")
+		fmt.Println("Usage: todo export <file>")
+		os.Exit(1)
+	}
+
+	if err := ExportTodoTxt(args[0]); err != nil {
+		fmt.Printf("Error exporting todos: %v\n", err)
 		os.Exit(2)
 	}
 
-	fmt.Printf("Task %d deleted\n", id)
+	fmt.Printf("Exported tasks to %s\n", args[0])
 }
 
 // showHelp displays usage information
@@ -205,9 +515,23 @@ func showHelp() {
 	fmt.Println("  todo add \"description\"   Add a new task")
 	fmt.Println("  todo list               List active tasks")
 	fmt.Println("  todo list --all         List all tasks (including completed)")
+	fmt.Println("  todo list +proj @ctx    Filter by project, context, priority:A, due:today, or text")
+	fmt.Println("  todo list --sort=priority --reverse  Sort by priority|created|due|id")
+	fmt.Println("  todo list --format=json|todotxt|i3status|plain  Choose output format")
+	fmt.Println("  todo list --include-blocked  Include tasks with unresolved dependencies")
 	fmt.Println("  todo complete <id>      Mark task as completed")
 	fmt.Println("  todo edit <id> \"desc\"    Update task description")
+	fmt.Println("  todo edit <id> --editor Edit a task's fields in $EDITOR")
+	fmt.Println("  todo edit-all           Edit the whole task list in $EDITOR")
 	fmt.Println("  todo delete <id>        Delete task permanently")
+	fmt.Println("  todo complete <id> --force  Complete a task despite unresolved dependencies")
+	fmt.Println("  todo note <id> \"text\"   Set a task's notes")
+	fmt.Println("  todo sub add <id> \"summary\"  Add a subtask")
+	fmt.Println("  todo sub done <id> <index>   Mark a subtask resolved")
+	fmt.Println("  todo block <id> --on <other-id>  Make a task depend on another")
+	fmt.Println("  todo import <file>      Import tasks from a todo.txt file")
+	fmt.Println("  todo export <file>      Export tasks to a todo.txt file")
+	fmt.Println("  todo sync               Sync tasks via the mailbox in ~/.todo.conf.yaml")
 	fmt.Println("  todo help               Show this help")
 	fmt.Println()
 	fmt.Println("Examples:")
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the IMAP/SMTP settings used by `todo sync`.
+type Config struct {
+	IMAPHost   string `yaml:"imap_host"`
+	IMAPUser   string `yaml:"imap_user"`
+	IMAPPass   string `yaml:"imap_pass"`
+	IMAPFolder string `yaml:"imap_folder"`
+	SMTPHost   string `yaml:"smtp_host"`
+	SMTPUser   string `yaml:"smtp_user"`
+	SMTPPass   string `yaml:"smtp_pass"`
+	SMTPFrom   string `yaml:"smtp_from"`
+}
+
+// GetConfigFilePath returns the path to the sync config file.
+func GetConfigFilePath() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".todo.conf.yaml"), nil
+}
+
+// LoadConfig loads the sync config file. It errors if the file is missing,
+// since sync cannot run without IMAP/SMTP credentials.
+func LoadConfig() (*Config, error) {
+	filePath, err := GetConfigFilePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config file %s: %w", filePath, err)
+	}
+
+	var config Config
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("cannot parse config file: %w", err)
+	}
+
+	return &config, nil
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// resolveEditorCommand returns the editor to launch, honoring $EDITOR, then
+// $VISUAL, and finally falling back to vi.
+func resolveEditorCommand() string {
+	if editor := os.Getenv("EDITOR"); editor != "" {
+		return editor
+	}
+	if visual := os.Getenv("VISUAL"); visual != "" {
+		return visual
+	}
+	return "vi"
+}
+
+// launchEditor writes initial to a temp file, opens it in the resolved
+// editor, and returns the file's contents after the editor exits.
+func launchEditor(initial string) (string, error) {
+	tmpFile, err := os.CreateTemp("", "todo-edit-*.tmp")
+	if err != nil {
+		return "", fmt.Errorf("cannot create temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmpFile.WriteString(initial); err != nil {
+		tmpFile.Close()
+		return "", fmt.Errorf("cannot write temp file: %w", err)
+	}
+	tmpFile.Close()
+
+	cmd := exec.Command(resolveEditorCommand(), tmpPath)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("editor exited with error: %w", err)
+	}
+
+	data, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return "", fmt.Errorf("cannot read edited file: %w", err)
+	}
+	return string(data), nil
+}
+
+// editTaskInEditor opens the given task as YAML in $EDITOR and merges the
+// user's changes back into the store. The whole read-edit-write cycle runs
+// under WithTodos, so the lock is held for as long as the editor is open.
+func editTaskInEditor(id int) {
+	err := WithTodos(func(todoData *TodoData) error {
+		task, exists := todoData.Tasks[id]
+		if !exists {
+			fmt.Printf("Error: Task ID %d not found\n", id)
+			os.Exit(1)
+		}
+
+		before, err := yaml.Marshal(task)
+		if err != nil {
+			fmt.Printf("Error preparing task for editing: %v\n", err)
+			os.Exit(2)
+		}
+
+		edited, err := launchEditor(string(before))
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(2)
+		}
+
+		var updated Task
+		if err := yaml.Unmarshal([]byte(edited), &updated); err != nil {
+			fmt.Printf("Error: edited task is not valid YAML: %v\n", err)
+			os.Exit(2)
+		}
+
+		task.Description = updated.Description
+		task.Notes = updated.Notes
+		task.Priority = updated.Priority
+		task.Projects = updated.Projects
+		task.Contexts = updated.Contexts
+		task.Tags = updated.Tags
+		task.Subtasks = updated.Subtasks
+		task.DependsOn = updated.DependsOn
+		task.Touch()
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Error saving todos: %v\n", err)
+		os.Exit(2)
+	}
+
+	fmt.Printf("Task %d updated\n", id)
+}
+
+// editAllInEditor opens the whole todo list as a tab-prefixed todo.txt
+// buffer ("<id>\t<todo.txt line>") in $EDITOR, then reparses it on save and
+// applies adds, edits, and deletes by ID. A line with no valid ID prefix is
+// treated as a new task. The read-edit-write cycle runs under WithTodos, so
+// the lock is held for as long as the editor is open.
+func editAllInEditor() {
+	err := WithTodos(func(todoData *TodoData) error {
+		ids := make([]int, 0, len(todoData.Tasks))
+		for id := range todoData.Tasks {
+			ids = append(ids, id)
+		}
+		sort.Ints(ids)
+
+		var before strings.Builder
+		for _, id := range ids {
+			fmt.Fprintf(&before, "%d\t%s\n", id, todoData.Tasks[id].FormatTodoTxt())
+		}
+
+		edited, err := launchEditor(before.String())
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(2)
+		}
+
+		newTasks := make(map[int]*Task)
+		for _, line := range strings.Split(edited, "\n") {
+			line = strings.TrimRight(line, "\r")
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+
+			id, rest, ok := splitEditedLine(line)
+			if !ok {
+				task := ParseTodoTxtLine(line)
+				task.ID = todoData.NextID
+				task.Created = time.Now()
+				task.UUID = newUUID()
+				task.Touch()
+				newTasks[task.ID] = task
+				todoData.NextID++
+				continue
+			}
+
+			task := ParseTodoTxtLine(rest)
+			task.ID = id
+			if task.Created.IsZero() {
+				task.Created = time.Now()
+			}
+			// The todo.txt buffer can't represent Notes, Subtasks, or
+			// DependsOn, so carry them over from the existing task rather
+			// than silently wiping them on every edit-all save.
+			if existing, wasPresent := todoData.Tasks[id]; wasPresent {
+				task.UUID = existing.UUID
+				task.Notes = existing.Notes
+				task.Subtasks = existing.Subtasks
+				task.DependsOn = existing.DependsOn
+			} else {
+				task.UUID = newUUID()
+			}
+			task.Touch()
+			newTasks[id] = task
+		}
+
+		todoData.Tasks = newTasks
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("Error saving todos: %v\n", err)
+		os.Exit(2)
+	}
+
+	fmt.Println("Task list updated")
+}
+
+// splitEditedLine splits a "<id>\t<rest>" line, reporting whether the prefix
+// was a valid task ID.
+func splitEditedLine(line string) (id int, rest string, ok bool) {
+	prefix, rest, found := strings.Cut(line, "\t")
+	if !found {
+		return 0, line, false
+	}
+	id, err := strconv.Atoi(prefix)
+	if err != nil {
+		return 0, line, false
+	}
+	return id, rest, true
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// FilterFunc reports whether a task matches some criterion. Filters are
+// combined by ParseFilterQuery with logical AND.
+type FilterFunc func(*Task) bool
+
+// ParseFilterQuery turns a space-separated query string into a single
+// FilterFunc. Supported tokens are +project, @context, priority:X,
+// due:<today|YYYY-MM-DD>, and free-text substrings matched against the
+// task description.
+func ParseFilterQuery(query string) FilterFunc {
+	tokens := strings.Fields(query)
+	filters := make([]FilterFunc, 0, len(tokens))
+	for _, token := range tokens {
+		filters = append(filters, parseFilterToken(token))
+	}
+
+	return func(t *Task) bool {
+		for _, filter := range filters {
+			if !filter(t) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func parseFilterToken(token string) FilterFunc {
+	switch {
+	case strings.HasPrefix(token, "+") && len(token) > 1:
+		project := token[1:]
+		return func(t *Task) bool { return containsFold(t.Projects, project) }
+	case strings.HasPrefix(token, "@") && len(token) > 1:
+		context := token[1:]
+		return func(t *Task) bool { return containsFold(t.Contexts, context) }
+	case strings.HasPrefix(token, "priority:"):
+		priority := strings.TrimPrefix(token, "priority:")
+		return func(t *Task) bool { return strings.EqualFold(t.Priority, priority) }
+	case strings.HasPrefix(token, "due:"):
+		return dueFilter(strings.TrimPrefix(token, "due:"))
+	default:
+		needle := strings.ToLower(token)
+		return func(t *Task) bool { return strings.Contains(strings.ToLower(t.Description), needle) }
+	}
+}
+
+func dueFilter(value string) FilterFunc {
+	if value == "today" {
+		today := time.Now().Format(todoTxtDateFormat)
+		return func(t *Task) bool { return t.Tags["due"] == today }
+	}
+	return func(t *Task) bool { return t.Tags["due"] == value }
+}
+
+func containsFold(haystack []string, needle string) bool {
+	for _, s := range haystack {
+		if strings.EqualFold(s, needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// SortTasks sorts tasks in place by the given field (priority, created, due,
+// or id), reversing the order when reverse is true.
+func SortTasks(tasks []*Task, sortBy string, reverse bool) {
+	less := sortLessFunc(sortBy, tasks)
+	sort.SliceStable(tasks, func(i, j int) bool {
+		if reverse {
+			return less(j, i)
+		}
+		return less(i, j)
+	})
+}
+
+func sortLessFunc(sortBy string, tasks []*Task) func(i, j int) bool {
+	switch sortBy {
+	case "priority":
+		return func(i, j int) bool {
+			pi, pj := tasks[i].Priority, tasks[j].Priority
+			if pi == "" {
+				return false
+			}
+			if pj == "" {
+				return true
+			}
+			return pi < pj
+		}
+	case "created":
+		return func(i, j int) bool { return tasks[i].Created.Before(tasks[j].Created) }
+	case "due":
+		return func(i, j int) bool {
+			di, dj := tasks[i].Tags["due"], tasks[j].Tags["due"]
+			if di == "" {
+				return false
+			}
+			if dj == "" {
+				return true
+			}
+			return di < dj
+		}
+	default:
+		return func(i, j int) bool { return tasks[i].ID < tasks[j].ID }
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import "testing"
+
+func TestParseFilterQuery(t *testing.T) {
+	tasks := map[string]*Task{
+		"project match":  {Description: "ship release", Projects: []string{"work"}},
+		"context match":  {Description: "call bank", Contexts: []string{"phone"}},
+		"priority match": {Description: "urgent fix", Priority: "A"},
+		"due match":      {Description: "renew passport", Tags: map[string]string{"due": "2024-05-01"}},
+		"text match":     {Description: "buy groceries"},
+		"non match":      {Description: "read book", Projects: []string{"leisure"}, Contexts: []string{"home"}, Priority: "C"},
+	}
+
+	cases := []struct {
+		query string
+		want  string
+	}{
+		{"+work", "project match"},
+		{"@phone", "context match"},
+		{"priority:a", "priority match"},
+		{"due:2024-05-01", "due match"},
+		{"groceries", "text match"},
+	}
+
+	for _, c := range cases {
+		filter := ParseFilterQuery(c.query)
+		if !filter(tasks[c.want]) {
+			t.Errorf("ParseFilterQuery(%q) did not match task %q", c.query, c.want)
+		}
+		for label, task := range tasks {
+			if label == c.want {
+				continue
+			}
+			if filter(task) {
+				t.Errorf("ParseFilterQuery(%q) unexpectedly matched task %q", c.query, label)
+			}
+		}
+	}
+}
+
+func TestParseFilterQueryCombinesTokensWithAnd(t *testing.T) {
+	matches := &Task{Description: "ship release", Projects: []string{"work"}, Priority: "A"}
+	missesPriority := &Task{Description: "ship release", Projects: []string{"work"}, Priority: "B"}
+
+	filter := ParseFilterQuery("+work priority:a")
+
+	if !filter(matches) {
+		t.Error("expected combined filter to match a task satisfying both tokens")
+	}
+	if filter(missesPriority) {
+		t.Error("expected combined filter to reject a task failing one token")
+	}
+}
+
+func TestSortTasksByPriority(t *testing.T) {
+	tasks := []*Task{
+		{ID: 1, Priority: ""},
+		{ID: 2, Priority: "B"},
+		{ID: 3, Priority: "A"},
+	}
+
+	SortTasks(tasks, "priority", false)
+
+	gotOrder := []int{tasks[0].ID, tasks[1].ID, tasks[2].ID}
+	wantOrder := []int{3, 2, 1}
+	for i := range gotOrder {
+		if gotOrder[i] != wantOrder[i] {
+			t.Fatalf("SortTasks order = %v, want %v", gotOrder, wantOrder)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// writeTasksPlain writes tasks in the original "<id>: <description> [DONE]"
+// format, marking unresolved dependencies as [BLOCKED].
+func writeTasksPlain(w io.Writer, tasks []*Task, todoData *TodoData) {
+	for _, task := range tasks {
+		status := ""
+		if task.Completed {
+			status = " [DONE]"
+		} else if IsTaskBlocked(todoData, task) {
+			status = " [BLOCKED]"
+		}
+		fmt.Fprintf(w, "%d: %s%s\n", task.ID, task.Description, status)
+	}
+}
+
+// writeTasksJSON dumps the full TodoData as indented JSON for scripting.
+func writeTasksJSON(w io.Writer, todoData *TodoData) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(todoData)
+}
+
+// writeTasksTodoTxt writes tasks in todo.txt format, one per line.
+func writeTasksTodoTxt(w io.Writer, tasks []*Task) {
+	for _, task := range tasks {
+		fmt.Fprintln(w, task.FormatTodoTxt())
+	}
+}
+
+// i3StatusBlock is the single-line JSON object consumed by i3status/i3bar.
+type i3StatusBlock struct {
+	Icon  string `json:"icon"`
+	State string `json:"state"`
+	Text  string `json:"text"`
+}
+
+// writeTasksI3Status writes a single i3status/i3bar JSON block summarizing
+// tasks, switching to the Warning state when any task is due within 24 hours.
+func writeTasksI3Status(w io.Writer, filterLabel string, tasks []*Task) error {
+	done := 0
+	warning := false
+	deadline := time.Now().Add(24 * time.Hour)
+
+	for _, task := range tasks {
+		if task.Completed {
+			done++
+			continue
+		}
+		if due, ok := task.Tags["due"]; ok {
+			if dueTime, err := time.Parse(todoTxtDateFormat, due); err == nil && dueTime.Before(deadline) {
+				warning = true
+			}
+		}
+	}
+
+	state := "Idle"
+	if warning {
+		state = "Warning"
+	}
+
+	block := i3StatusBlock{
+		Icon:  "tasks",
+		State: state,
+		Text:  fmt.Sprintf("%s: %d/%d", filterLabel, done, len(tasks)),
+	}
+
+	data, err := json.Marshal(block)
+	if err != nil {
+		return fmt.Errorf("cannot encode i3status block: %w", err)
+	}
+	fmt.Fprintln(w, string(data))
+	return nil
+}
@@ -0,0 +1,20 @@
+//go:build unix
+
+package main
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// acquireLock takes an exclusive advisory flock(2) on f, blocking until it's
+// available.
+func acquireLock(f *os.File) error {
+	return unix.Flock(int(f.Fd()), unix.LOCK_EX)
+}
+
+// releaseLock releases a lock previously taken by acquireLock.
+func releaseLock(f *os.File) error {
+	return unix.Flock(int(f.Fd()), unix.LOCK_UN)
+}
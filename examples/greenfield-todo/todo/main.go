@@ -8,7 +8,7 @@ import (
 func main() {
 	// If no arguments, default to listing active tasks
 	if len(os.Args) < 2 {
-		listTasks(false)
+		listTasks(os.Stdout, nil)
 		return
 	}
 
@@ -19,14 +19,27 @@ func main() {
 	case "add":
 		addTask(args)
 	case "list":
-		showAll := len(args) > 0 && args[0] == "--all"
-		listTasks(showAll)
+		listTasks(os.Stdout, args)
 	case "complete":
 		completeTask(args)
 	case "edit":
 		editTask(args)
+	case "edit-all":
+		editAllInEditor()
 	case "delete":
 		deleteTask(args)
+	case "import":
+		importTasks(args)
+	case "export":
+		exportTasks(args)
+	case "note":
+		noteTask(args)
+	case "sub":
+		subCommand(args)
+	case "block":
+		blockTask(args)
+	case "sync":
+		syncTasks(args)
 	case "help":
 		showHelp()
 	default:
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/rand"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -45,9 +46,35 @@ func LoadTodos() (*TodoData, error) {
 		todoData.Tasks = make(map[int]*Task)
 	}
 
+	// Assign UUIDs to any task that predates the sync feature. This only
+	// fills them in in-memory: LoadTodos is a read and must never write the
+	// file without the lock WithTodos holds, so the migration is persisted
+	// the next time a WithTodos-guarded write saves this data.
+	ensureTaskUUIDs(&todoData)
+
 	return &todoData, nil
 }
 
+// ensureTaskUUIDs assigns a UUID to any task that doesn't already have one.
+func ensureTaskUUIDs(todoData *TodoData) {
+	for _, task := range todoData.Tasks {
+		if task.UUID == "" {
+			task.UUID = newUUID()
+		}
+	}
+}
+
+// newUUID generates a random RFC 4122 version 4 UUID.
+func newUUID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
 // SaveTodos saves the todo data to the YAML file atomically
 func SaveTodos(todoData *TodoData) error {
 	filePath, err := GetTodoFilePath()
@@ -74,3 +101,58 @@ func SaveTodos(todoData *TodoData) error {
 
 	return nil
 }
+
+// maxWithTodosAttempts bounds the optimistic-concurrency retry loop in
+// WithTodos so a file that keeps changing underneath us doesn't spin forever.
+const maxWithTodosAttempts = 3
+
+// WithTodos runs fn against the current todo store while holding an
+// exclusive lock on the todo file for the whole read-modify-write cycle, so
+// two concurrent `todo` invocations can't silently clobber each other's
+// NextID. As a second line of defense against writers that bypass the lock,
+// it also checks TodoData.Revision before saving and retries fn if another
+// process's write slipped in underneath. All CLI commands that mutate the
+// store should go through this instead of pairing LoadTodos with SaveTodos
+// directly.
+func WithTodos(fn func(*TodoData) error) error {
+	filePath, err := GetTodoFilePath()
+	if err != nil {
+		return err
+	}
+
+	lockFile, err := os.OpenFile(filePath+".lock", os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("cannot open todo lock file: %w", err)
+	}
+	defer lockFile.Close()
+
+	if err := acquireLock(lockFile); err != nil {
+		return fmt.Errorf("cannot acquire todo file lock: %w", err)
+	}
+	defer releaseLock(lockFile)
+
+	for attempt := 0; attempt < maxWithTodosAttempts; attempt++ {
+		todoData, err := LoadTodos()
+		if err != nil {
+			return err
+		}
+		startRevision := todoData.Revision
+
+		if err := fn(todoData); err != nil {
+			return err
+		}
+
+		current, err := LoadTodos()
+		if err != nil {
+			return err
+		}
+		if current.Revision != startRevision {
+			continue // todo file changed underneath us; reload and retry
+		}
+
+		todoData.Revision++
+		return SaveTodos(todoData)
+	}
+
+	return fmt.Errorf("todo file kept changing concurrently, giving up after %d attempts", maxWithTodosAttempts)
+}
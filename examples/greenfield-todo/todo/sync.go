@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"net/smtp"
+	"net/textproto"
+
+	"github.com/emersion/go-imap"
+	"github.com/emersion/go-imap/client"
+	"gopkg.in/yaml.v3"
+)
+
+// Repository is a store of tasks keyed by their stable UUID, so the local
+// and remote sides of a sync can be treated uniformly.
+type Repository interface {
+	FindAll() ([]*Task, error)
+	Upsert(task *Task) error
+	Delete(uuid string) error
+}
+
+// MemoryRepo adapts the local YAML-backed store to the Repository
+// interface, keyed by Task.UUID instead of the per-device integer ID.
+type MemoryRepo struct{}
+
+func (r *MemoryRepo) FindAll() ([]*Task, error) {
+	var tasks []*Task
+	// Read via WithTodos, not a bare LoadTodos, so that any UUID LoadTodos
+	// assigns in-memory to a legacy task gets persisted by WithTodos's save.
+	// Otherwise a task that predates the sync feature would get a fresh
+	// random UUID on every sync run, never matching what was pushed last
+	// time.
+	err := WithTodos(func(todoData *TodoData) error {
+		tasks = make([]*Task, 0, len(todoData.Tasks))
+		for _, task := range todoData.Tasks {
+			tasks = append(tasks, task)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *MemoryRepo) Upsert(task *Task) error {
+	return WithTodos(func(todoData *TodoData) error {
+		for _, existing := range todoData.Tasks {
+			if existing.UUID == task.UUID {
+				// The incoming task carries whatever ID it had on the other
+				// device; keep our own so the map key and Task.ID never
+				// diverge and a printed ID keeps pointing at this task.
+				task.ID = existing.ID
+				*existing = *task
+				return nil
+			}
+		}
+
+		task.ID = todoData.NextID
+		todoData.Tasks[task.ID] = task
+		todoData.NextID++
+		return nil
+	})
+}
+
+func (r *MemoryRepo) Delete(uuid string) error {
+	return WithTodos(func(todoData *TodoData) error {
+		for id, existing := range todoData.Tasks {
+			if existing.UUID == uuid {
+				delete(todoData.Tasks, id)
+				return nil
+			}
+		}
+		return nil
+	})
+}
+
+// uuidHeader is the email header used to find a task's message again when
+// updating or deleting it remotely.
+const uuidHeader = "X-Todo-Uuid"
+
+// RemoteRepo stores each task as one email in a designated IMAP folder,
+// with the task's YAML as the message body. Outbound writes are delivered
+// via SMTP to the same mailbox; the mail server is expected to file them
+// into IMAPFolder.
+type RemoteRepo struct {
+	config *Config
+}
+
+// NewRemoteRepo creates a RemoteRepo backed by the given IMAP/SMTP config.
+func NewRemoteRepo(config *Config) *RemoteRepo {
+	return &RemoteRepo{config: config}
+}
+
+func (r *RemoteRepo) dial() (*client.Client, error) {
+	c, err := client.DialTLS(r.config.IMAPHost, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to imap server: %w", err)
+	}
+	if err := c.Login(r.config.IMAPUser, r.config.IMAPPass); err != nil {
+		c.Logout()
+		return nil, fmt.Errorf("cannot log in to imap server: %w", err)
+	}
+	return c, nil
+}
+
+func (r *RemoteRepo) FindAll() ([]*Task, error) {
+	c, err := r.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer c.Logout()
+
+	mbox, err := c.Select(r.config.IMAPFolder, false)
+	if err != nil {
+		return nil, fmt.Errorf("cannot select imap folder %s: %w", r.config.IMAPFolder, err)
+	}
+	if mbox.Messages == 0 {
+		return nil, nil
+	}
+
+	seqset := new(imap.SeqSet)
+	seqset.AddRange(1, mbox.Messages)
+
+	section := &imap.BodySectionName{}
+	messages := make(chan *imap.Message, mbox.Messages)
+	fetchDone := make(chan error, 1)
+	go func() {
+		fetchDone <- c.Fetch(seqset, []imap.FetchItem{section.FetchItem()}, messages)
+	}()
+
+	var tasks []*Task
+	for msg := range messages {
+		body := msg.GetBody(section)
+		if body == nil {
+			continue
+		}
+		task, err := parseTaskEmail(body)
+		if err != nil {
+			continue
+		}
+		tasks = append(tasks, task)
+	}
+	if err := <-fetchDone; err != nil {
+		return nil, fmt.Errorf("cannot fetch imap messages: %w", err)
+	}
+
+	return tasks, nil
+}
+
+func (r *RemoteRepo) Upsert(task *Task) error {
+	if err := r.Delete(task.UUID); err != nil {
+		return err
+	}
+	return r.send(task)
+}
+
+func (r *RemoteRepo) send(task *Task) error {
+	data, err := yaml.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("cannot marshal task: %w", err)
+	}
+
+	msg := fmt.Sprintf("Subject: %s\r\n%s: %s\r\n\r\n%s", task.Description, uuidHeader, task.UUID, data)
+
+	auth := smtp.PlainAuth("", r.config.SMTPUser, r.config.SMTPPass, smtpHostname(r.config.SMTPHost))
+	if err := smtp.SendMail(r.config.SMTPHost, auth, r.config.SMTPFrom, []string{r.config.SMTPUser}, []byte(msg)); err != nil {
+		return fmt.Errorf("cannot send task email: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RemoteRepo) Delete(uuid string) error {
+	c, err := r.dial()
+	if err != nil {
+		return err
+	}
+	defer c.Logout()
+
+	if _, err := c.Select(r.config.IMAPFolder, false); err != nil {
+		return fmt.Errorf("cannot select imap folder %s: %w", r.config.IMAPFolder, err)
+	}
+
+	criteria := imap.NewSearchCriteria()
+	criteria.Header = textproto.MIMEHeader{uuidHeader: []string{uuid}}
+	seqNums, err := c.Search(criteria)
+	if err != nil {
+		return fmt.Errorf("cannot search imap folder: %w", err)
+	}
+	if len(seqNums) == 0 {
+		return nil
+	}
+
+	seqset := new(imap.SeqSet)
+	seqset.AddNum(seqNums...)
+	if err := c.Store(seqset, imap.FormatFlagsOp(imap.AddFlags, true), []interface{}{imap.DeletedFlag}, nil); err != nil {
+		return fmt.Errorf("cannot flag imap message as deleted: %w", err)
+	}
+
+	return c.Expunge(nil)
+}
+
+func parseTaskEmail(body io.Reader) (*Task, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read imap message body: %w", err)
+	}
+
+	_, yamlPart, found := bytes.Cut(data, []byte("\r\n\r\n"))
+	if !found {
+		yamlPart = data
+	}
+
+	var task Task
+	if err := yaml.Unmarshal(yamlPart, &task); err != nil {
+		return nil, fmt.Errorf("cannot parse task email: %w", err)
+	}
+	return &task, nil
+}
+
+func smtpHostname(hostPort string) string {
+	host, _, err := net.SplitHostPort(hostPort)
+	if err != nil {
+		return hostPort
+	}
+	return host
+}
+
+// SyncTasks reconciles the local store with the remote IMAP/SMTP mailbox,
+// using last-write-wins (by Modified) to resolve conflicts on the same
+// UUID, then writes the merged state back locally.
+func SyncTasks(config *Config) (int, error) {
+	return syncRepos(&MemoryRepo{}, NewRemoteRepo(config))
+}
+
+// syncRepos reconciles local against remote by UUID. It only ever writes a
+// task to one side: pulling it into local when remote is newer, or pushing
+// it to remote when local is newer (or remote doesn't have it yet). A task
+// that was just pulled from remote is never pushed back in the same pass,
+// otherwise the just-merged newer remote copy would be immediately stomped
+// by the stale local one it replaced.
+func syncRepos(local, remote Repository) (int, error) {
+	localTasks, err := local.FindAll()
+	if err != nil {
+		return 0, err
+	}
+	remoteTasks, err := remote.FindAll()
+	if err != nil {
+		return 0, err
+	}
+
+	localByUUID := make(map[string]*Task, len(localTasks))
+	for _, task := range localTasks {
+		localByUUID[task.UUID] = task
+	}
+	remoteByUUID := make(map[string]*Task, len(remoteTasks))
+	for _, task := range remoteTasks {
+		remoteByUUID[task.UUID] = task
+	}
+
+	changed := 0
+	pulled := make(map[string]bool, len(remoteTasks))
+	for _, remoteTask := range remoteTasks {
+		localTask, exists := localByUUID[remoteTask.UUID]
+		if !exists || remoteTask.Modified.After(localTask.Modified) {
+			if err := local.Upsert(remoteTask); err != nil {
+				return changed, err
+			}
+			changed++
+			pulled[remoteTask.UUID] = true
+		}
+	}
+
+	for _, localTask := range localTasks {
+		if pulled[localTask.UUID] {
+			continue // remote was newer and has already won; don't push it back
+		}
+		if remoteTask, existsRemotely := remoteByUUID[localTask.UUID]; existsRemotely {
+			if !localTask.Modified.After(remoteTask.Modified) {
+				continue // remote is already current; nothing to push
+			}
+		}
+		if err := remote.Upsert(localTask); err != nil {
+			return changed, err
+		}
+	}
+
+	return changed, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeRepo is an in-memory Repository used to test syncRepos without
+// touching the real YAML store or an IMAP/SMTP server.
+type fakeRepo struct {
+	tasks map[string]*Task
+}
+
+func newFakeRepo(tasks ...*Task) *fakeRepo {
+	r := &fakeRepo{tasks: make(map[string]*Task)}
+	for _, task := range tasks {
+		r.tasks[task.UUID] = task
+	}
+	return r
+}
+
+func (r *fakeRepo) FindAll() ([]*Task, error) {
+	tasks := make([]*Task, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (r *fakeRepo) Upsert(task *Task) error {
+	r.tasks[task.UUID] = task
+	return nil
+}
+
+func (r *fakeRepo) Delete(uuid string) error {
+	delete(r.tasks, uuid)
+	return nil
+}
+
+func TestSyncReposPullsNewerRemoteTask(t *testing.T) {
+	older := time.Now().Add(-time.Hour)
+	newer := time.Now()
+
+	local := newFakeRepo(&Task{UUID: "shared", Description: "old local copy", Modified: older})
+	remote := newFakeRepo(&Task{UUID: "shared", Description: "new remote copy", Modified: newer})
+
+	changed, err := syncRepos(local, remote)
+	if err != nil {
+		t.Fatalf("syncRepos: %v", err)
+	}
+	if changed != 1 {
+		t.Errorf("changed = %d, want 1", changed)
+	}
+	if got := local.tasks["shared"].Description; got != "new remote copy" {
+		t.Errorf("local task after sync = %q, want %q", got, "new remote copy")
+	}
+}
+
+func TestSyncReposDoesNotStompJustPulledRemoteTask(t *testing.T) {
+	older := time.Now().Add(-time.Hour)
+	newer := time.Now()
+
+	local := newFakeRepo(&Task{UUID: "shared", Description: "stale local copy", Modified: older})
+	remote := newFakeRepo(&Task{UUID: "shared", Description: "fresh remote copy", Modified: newer})
+
+	if _, err := syncRepos(local, remote); err != nil {
+		t.Fatalf("syncRepos: %v", err)
+	}
+
+	// The remote task just won the merge; it must not be pushed back with
+	// the stale local snapshot taken before the merge happened.
+	if got := remote.tasks["shared"].Description; got != "fresh remote copy" {
+		t.Errorf("remote task after sync = %q, want %q (remote copy should survive)", got, "fresh remote copy")
+	}
+}
+
+func TestSyncReposPushesNewerLocalTask(t *testing.T) {
+	older := time.Now().Add(-time.Hour)
+	newer := time.Now()
+
+	local := newFakeRepo(&Task{UUID: "shared", Description: "fresh local copy", Modified: newer})
+	remote := newFakeRepo(&Task{UUID: "shared", Description: "stale remote copy", Modified: older})
+
+	if _, err := syncRepos(local, remote); err != nil {
+		t.Fatalf("syncRepos: %v", err)
+	}
+	if got := remote.tasks["shared"].Description; got != "fresh local copy" {
+		t.Errorf("remote task after sync = %q, want %q", got, "fresh local copy")
+	}
+}
+
+func TestSyncReposPushesLocalOnlyTask(t *testing.T) {
+	local := newFakeRepo(&Task{UUID: "local-only", Description: "new task"})
+	remote := newFakeRepo()
+
+	if _, err := syncRepos(local, remote); err != nil {
+		t.Fatalf("syncRepos: %v", err)
+	}
+	if _, exists := remote.tasks["local-only"]; !exists {
+		t.Error("expected local-only task to be pushed to remote")
+	}
+}
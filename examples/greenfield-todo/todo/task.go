@@ -4,26 +4,45 @@ import "time"
 
 // Task represents a single todo item
 type Task struct {
-	ID          int        `yaml:"id"`
-	Description string     `yaml:"description"`
-	Created     time.Time  `yaml:"created"`
-	Completed   bool       `yaml:"completed"`
-	CompletedAt *time.Time `yaml:"completed_at,omitempty"`
+	ID          int               `yaml:"id" json:"id"`
+	Description string            `yaml:"description" json:"description"`
+	Created     time.Time         `yaml:"created" json:"created"`
+	Completed   bool              `yaml:"completed" json:"completed"`
+	CompletedAt *time.Time        `yaml:"completed_at,omitempty" json:"completed_at,omitempty"`
+	Priority    string            `yaml:"priority,omitempty" json:"priority,omitempty"`
+	Projects    []string          `yaml:"projects,omitempty" json:"projects,omitempty"`
+	Contexts    []string          `yaml:"contexts,omitempty" json:"contexts,omitempty"`
+	Tags        map[string]string `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Notes       string            `yaml:"notes,omitempty" json:"notes,omitempty"`
+	Subtasks    []SubTask         `yaml:"subtasks,omitempty" json:"subtasks,omitempty"`
+	DependsOn   []int             `yaml:"depends_on,omitempty" json:"depends_on,omitempty"`
+	UUID        string            `yaml:"uuid,omitempty" json:"uuid,omitempty"`
+	Modified    time.Time         `yaml:"modified,omitempty" json:"modified,omitempty"`
+}
+
+// SubTask is a small checklist item attached to a Task.
+type SubTask struct {
+	Summary  string `yaml:"summary" json:"summary"`
+	Resolved bool   `yaml:"resolved" json:"resolved"`
 }
 
 // TodoData represents the entire todo file structure
 type TodoData struct {
-	NextID int            `yaml:"next_id"`
-	Tasks  map[int]*Task  `yaml:"tasks"`
+	NextID   int           `yaml:"next_id" json:"next_id"`
+	Tasks    map[int]*Task `yaml:"tasks" json:"tasks"`
+	Revision int           `yaml:"revision" json:"revision"`
 }
 
 // NewTask creates a new task with the given ID and description
 func NewTask(id int, description string) *Task {
+	now := time.Now()
 	return &Task{
 		ID:          id,
 		Description: description,
-		Created:     time.Now(),
+		Created:     now,
 		Completed:   false,
+		UUID:        newUUID(),
+		Modified:    now,
 	}
 }
 
@@ -32,6 +51,25 @@ func (t *Task) MarkCompleted() {
 	t.Completed = true
 	now := time.Now()
 	t.CompletedAt = &now
+	t.Touch()
+}
+
+// Touch updates the task's Modified timestamp, used by sync to pick a
+// last-write-wins winner when the same task changed on two devices.
+func (t *Task) Touch() {
+	t.Modified = time.Now()
+}
+
+// IsTaskBlocked reports whether task has any dependency in DependsOn that is
+// missing or not yet completed.
+func IsTaskBlocked(todoData *TodoData, task *Task) bool {
+	for _, depID := range task.DependsOn {
+		dep, exists := todoData.Tasks[depID]
+		if !exists || !dep.Completed {
+			return true
+		}
+	}
+	return false
 }
 
 // NewTodoData creates an empty TodoData structure
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const todoTxtDateFormat = "2006-01-02"
+
+var priorityPattern = regexp.MustCompile(`^\(([A-Z])\)$`)
+
+// tagKeyPattern restricts key:value tag keys to identifier-like tokens
+// (starting with a letter), so a time (12:30) or a ratio (3:2) in the
+// description isn't mistaken for a tag. URLs (http://...) have a valid key
+// and are rejected separately by isTagToken.
+var tagKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// ParseTodoTxtLine parses a single todo.txt formatted line into a Task.
+// It understands the leading completion marker and dates, a (X) priority,
+// +project and @context tokens, and key:value tags anywhere in the line.
+func ParseTodoTxtLine(line string) *Task {
+	task := &Task{}
+	fields := strings.Fields(line)
+	i := 0
+
+	if i < len(fields) && fields[i] == "x" {
+		task.Completed = true
+		i++
+		if i < len(fields) {
+			if d, err := time.Parse(todoTxtDateFormat, fields[i]); err == nil {
+				task.CompletedAt = &d
+				i++
+			}
+		}
+	} else if i < len(fields) {
+		if m := priorityPattern.FindStringSubmatch(fields[i]); m != nil {
+			task.Priority = m[1]
+			i++
+		}
+	}
+
+	if i < len(fields) {
+		if d, err := time.Parse(todoTxtDateFormat, fields[i]); err == nil {
+			task.Created = d
+			i++
+		}
+	}
+
+	var words []string
+	for ; i < len(fields); i++ {
+		word := fields[i]
+		switch {
+		case strings.HasPrefix(word, "+") && len(word) > 1:
+			task.Projects = append(task.Projects, word[1:])
+		case strings.HasPrefix(word, "@") && len(word) > 1:
+			task.Contexts = append(task.Contexts, word[1:])
+		case isTagToken(word):
+			key, value, _ := strings.Cut(word, ":")
+			if task.Tags == nil {
+				task.Tags = make(map[string]string)
+			}
+			task.Tags[key] = value
+		default:
+			words = append(words, word)
+		}
+	}
+	task.Description = strings.Join(words, " ")
+
+	return task
+}
+
+// isTagToken reports whether word looks like a key:value tag such as
+// due:2024-01-20, as opposed to incidental colons in a URL, a time, or a
+// ratio (e.g. http://example.com, 12:30, 3:2).
+func isTagToken(word string) bool {
+	key, value, found := strings.Cut(word, ":")
+	if !found || key == "" || value == "" {
+		return false
+	}
+	if !tagKeyPattern.MatchString(key) {
+		return false
+	}
+	return !strings.HasPrefix(value, "//")
+}
+
+// FormatTodoTxt renders a Task as a single todo.txt formatted line.
+func (t *Task) FormatTodoTxt() string {
+	var b strings.Builder
+
+	if t.Completed {
+		b.WriteString("x ")
+		if t.CompletedAt != nil {
+			b.WriteString(t.CompletedAt.Format(todoTxtDateFormat))
+			b.WriteString(" ")
+		}
+	} else if t.Priority != "" {
+		b.WriteString(fmt.Sprintf("(%s) ", t.Priority))
+	}
+
+	if !t.Created.IsZero() {
+		b.WriteString(t.Created.Format(todoTxtDateFormat))
+		b.WriteString(" ")
+	}
+
+	b.WriteString(t.Description)
+
+	for _, project := range t.Projects {
+		b.WriteString(" +" + project)
+	}
+	for _, context := range t.Contexts {
+		b.WriteString(" @" + context)
+	}
+	for _, key := range sortedTagKeys(t.Tags) {
+		b.WriteString(fmt.Sprintf(" %s:%s", key, t.Tags[key]))
+	}
+
+	return b.String()
+}
+
+func sortedTagKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for key := range tags {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// ImportTodoTxt reads a todo.txt file and appends its tasks to the store,
+// returning the number of tasks imported.
+func ImportTodoTxt(filePath string) (int, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("cannot open todo.txt file: %w", err)
+	}
+	defer file.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("cannot read todo.txt file: %w", err)
+	}
+
+	imported := 0
+	err = WithTodos(func(todoData *TodoData) error {
+		for _, line := range lines {
+			task := ParseTodoTxtLine(line)
+			task.ID = todoData.NextID
+			if task.Created.IsZero() {
+				task.Created = time.Now()
+			}
+			task.UUID = newUUID()
+			task.Touch()
+			todoData.Tasks[task.ID] = task
+			todoData.NextID++
+			imported++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return imported, nil
+}
+
+// ExportTodoTxt writes the current store to filePath in todo.txt format,
+// ordered by task ID.
+func ExportTodoTxt(filePath string) error {
+	todoData, err := LoadTodos()
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int, 0, len(todoData.Tasks))
+	for id := range todoData.Tasks {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var b strings.Builder
+	for _, id := range ids {
+		b.WriteString(todoData.Tasks[id].FormatTodoTxt())
+		b.WriteString("\n")
+	}
+
+	if err := os.WriteFile(filePath, []byte(b.String()), 0600); err != nil {
+		return fmt.Errorf("cannot write todo.txt file: %w", err)
+	}
+
+	return nil
+}
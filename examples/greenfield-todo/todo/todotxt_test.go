@@ -0,0 +1,97 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseTodoTxtLineRoundTrip(t *testing.T) {
+	created, err := time.Parse(todoTxtDateFormat, "2024-01-15")
+	if err != nil {
+		t.Fatalf("test setup: %v", err)
+	}
+
+	original := &Task{
+		Priority:    "A",
+		Created:     created,
+		Description: "write report",
+		Projects:    []string{"work"},
+		Contexts:    []string{"office"},
+		Tags:        map[string]string{"due": "2024-02-01"},
+	}
+
+	line := original.FormatTodoTxt()
+	parsed := ParseTodoTxtLine(line)
+
+	if parsed.Priority != original.Priority {
+		t.Errorf("Priority = %q, want %q", parsed.Priority, original.Priority)
+	}
+	if !parsed.Created.Equal(original.Created) {
+		t.Errorf("Created = %v, want %v", parsed.Created, original.Created)
+	}
+	if parsed.Description != original.Description {
+		t.Errorf("Description = %q, want %q", parsed.Description, original.Description)
+	}
+	if !reflect.DeepEqual(parsed.Projects, original.Projects) {
+		t.Errorf("Projects = %v, want %v", parsed.Projects, original.Projects)
+	}
+	if !reflect.DeepEqual(parsed.Contexts, original.Contexts) {
+		t.Errorf("Contexts = %v, want %v", parsed.Contexts, original.Contexts)
+	}
+	if !reflect.DeepEqual(parsed.Tags, original.Tags) {
+		t.Errorf("Tags = %v, want %v", parsed.Tags, original.Tags)
+	}
+}
+
+func TestParseTodoTxtLineCompleted(t *testing.T) {
+	completedAt, err := time.Parse(todoTxtDateFormat, "2024-03-10")
+	if err != nil {
+		t.Fatalf("test setup: %v", err)
+	}
+
+	line := "x 2024-03-10 pay invoice +billing"
+	task := ParseTodoTxtLine(line)
+
+	if !task.Completed {
+		t.Fatal("expected task to be marked completed")
+	}
+	if task.CompletedAt == nil || !task.CompletedAt.Equal(completedAt) {
+		t.Errorf("CompletedAt = %v, want %v", task.CompletedAt, completedAt)
+	}
+	if task.Description != "pay invoice" {
+		t.Errorf("Description = %q, want %q", task.Description, "pay invoice")
+	}
+	if !reflect.DeepEqual(task.Projects, []string{"billing"}) {
+		t.Errorf("Projects = %v, want [billing]", task.Projects)
+	}
+}
+
+func TestFormatTodoTxtOmitsPriorityWhenCompleted(t *testing.T) {
+	task := &Task{Completed: true, Priority: "A", Description: "done already"}
+	line := task.FormatTodoTxt()
+	if line != "x done already" {
+		t.Errorf("FormatTodoTxt() = %q, want %q", line, "x done already")
+	}
+}
+
+func TestParseTodoTxtLineDoesNotMangleColonsInDescription(t *testing.T) {
+	cases := []struct {
+		line string
+		want string
+	}{
+		{"Check http://example.com for details", "Check http://example.com for details"},
+		{"meet at 12:30 today", "meet at 12:30 today"},
+		{"mix ratio 3:2 concrete", "mix ratio 3:2 concrete"},
+	}
+
+	for _, c := range cases {
+		task := ParseTodoTxtLine(c.line)
+		if task.Description != c.want {
+			t.Errorf("ParseTodoTxtLine(%q).Description = %q, want %q", c.line, task.Description, c.want)
+		}
+		if len(task.Tags) != 0 {
+			t.Errorf("ParseTodoTxtLine(%q).Tags = %v, want none", c.line, task.Tags)
+		}
+	}
+}